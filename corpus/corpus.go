@@ -0,0 +1,469 @@
+// Package corpus maintains a local, persistent cache of GitHub issues, pull
+// requests, commits and repositories, modeled after golang.org/x/build/maintner:
+// every observed change is appended to an on-disk mutation log and replayed
+// into an in-memory index on startup, so repeated reads against a dashboard
+// don't need to re-paginate the API on every call.
+package corpus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
+)
+
+type mutationKind int
+
+const (
+	mutationRepo mutationKind = iota
+	mutationIssue
+	mutationPullRequest
+	mutationCommit
+	mutationSince
+)
+
+// mutation is a single durable change applied to the corpus. Mutations are
+// appended to the on-disk log in the order they are observed and replayed in
+// that same order to reconstruct the in-memory model.
+type mutation struct {
+	Kind mutationKind
+	Repo string // "owner/repo", unset for mutationRepo
+
+	Repository  *github.Repository
+	Issue       *github.Issue
+	PullRequest *github.PullRequest
+	Commit      *github.RepositoryCommit
+
+	// Since fields are set for mutationSince, recording the high-water marks
+	// a Sync/Update call advanced Repo to, so HasRepo/Update survive a
+	// restart instead of resetting to "never synced".
+	SinceIssue  time.Time
+	SincePR     time.Time
+	SinceCommit time.Time
+}
+
+// Corpus is a local cache of GitHub objects, keyed by repo full name / issue
+// number / commit SHA, backed by an append-only mutation log on disk.
+type Corpus struct {
+	client *github.Client
+
+	logPath string
+	log     *os.File
+	mu      sync.RWMutex
+
+	repos        map[string]github.Repository
+	issues       map[string]map[int]github.Issue
+	pullRequests map[string]map[int]github.PullRequest
+	commits      map[string]map[string]github.RepositoryCommit
+
+	sinceIssue  map[string]time.Time
+	sincePR     map[string]time.Time
+	sinceCommit map[string]time.Time
+}
+
+// New opens (creating if necessary) the mutation log at logPath, replays it
+// to rebuild the in-memory model, and returns a Corpus ready for querying and
+// further updates. Objects are fetched through client as Sync/Update are
+// called; New itself performs no network I/O.
+func New(client *github.Client, logPath string) (*Corpus, error) {
+	c := &Corpus{
+		client:       client,
+		logPath:      logPath,
+		repos:        make(map[string]github.Repository),
+		issues:       make(map[string]map[int]github.Issue),
+		pullRequests: make(map[string]map[int]github.PullRequest),
+		commits:      make(map[string]map[string]github.RepositoryCommit),
+		sinceIssue:   make(map[string]time.Time),
+		sincePR:      make(map[string]time.Time),
+		sinceCommit:  make(map[string]time.Time),
+	}
+
+	if err := c.replay(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	c.log = f
+
+	return c, nil
+}
+
+// replay reads every mutation previously appended to the log and applies it
+// to the in-memory model, reconstructing corpus state on process restart.
+//
+// Each record is length-prefixed and decoded with its own gob.Decoder (see
+// appendMutation): a gob stream carries its type definitions inline, so
+// reading multiple records off a single long-lived Decoder fails as soon as a
+// later record's encoder re-sends a type the decoder already has ("gob:
+// duplicate type received"). Framing records independently avoids that.
+func (c *Corpus) replay() error {
+	f, err := os.Open(c.logPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("corpus: replaying log %s: %v", c.logPath, err)
+		}
+
+		buf := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return fmt.Errorf("corpus: replaying log %s: %v", c.logPath, err)
+		}
+
+		var m mutation
+		if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(&m); err != nil {
+			return fmt.Errorf("corpus: replaying log %s: %v", c.logPath, err)
+		}
+		c.applyLocked(&m)
+	}
+	return nil
+}
+
+// appendMutation durably records m and applies it to the in-memory model.
+// The record is encoded with a fresh gob.Encoder and written length-prefixed,
+// so replay can decode it with a fresh gob.Decoder independently of every
+// other record (see replay).
+func (c *Corpus) appendMutation(m *mutation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		return fmt.Errorf("corpus: encoding mutation: %v", err)
+	}
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(buf.Len()))
+	if _, err := c.log.Write(size[:]); err != nil {
+		return fmt.Errorf("corpus: appending mutation: %v", err)
+	}
+	if _, err := c.log.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("corpus: appending mutation: %v", err)
+	}
+
+	c.applyLocked(m)
+	return nil
+}
+
+func (c *Corpus) applyLocked(m *mutation) {
+	switch m.Kind {
+	case mutationRepo:
+		c.repos[*m.Repository.FullName] = *m.Repository
+	case mutationIssue:
+		if c.issues[m.Repo] == nil {
+			c.issues[m.Repo] = make(map[int]github.Issue)
+		}
+		c.issues[m.Repo][*m.Issue.Number] = *m.Issue
+	case mutationPullRequest:
+		if c.pullRequests[m.Repo] == nil {
+			c.pullRequests[m.Repo] = make(map[int]github.PullRequest)
+		}
+		c.pullRequests[m.Repo][*m.PullRequest.Number] = *m.PullRequest
+	case mutationCommit:
+		if c.commits[m.Repo] == nil {
+			c.commits[m.Repo] = make(map[string]github.RepositoryCommit)
+		}
+		c.commits[m.Repo][*m.Commit.SHA] = *m.Commit
+	case mutationSince:
+		c.sinceIssue[m.Repo] = m.SinceIssue
+		c.sincePR[m.Repo] = m.SincePR
+		c.sinceCommit[m.Repo] = m.SinceCommit
+	}
+}
+
+// recordSince durably advances repo's since high-water marks, so a restart's
+// replay reconstructs HasRepo and Update's resume point instead of forgetting
+// that repo was ever synced.
+func (c *Corpus) recordSince(repo string, sinceIssue, sincePR, sinceCommit time.Time) error {
+	return c.appendMutation(&mutation{
+		Kind:        mutationSince,
+		Repo:        repo,
+		SinceIssue:  sinceIssue,
+		SincePR:     sincePR,
+		SinceCommit: sinceCommit,
+	})
+}
+
+// UpsertRepo applies a repository mutation, keyed by its full name.
+func (c *Corpus) UpsertRepo(repo github.Repository) error {
+	return c.appendMutation(&mutation{Kind: mutationRepo, Repository: &repo})
+}
+
+// UpsertIssue applies an issue mutation for repo, keyed by issue number.
+func (c *Corpus) UpsertIssue(repo string, issue github.Issue) error {
+	return c.appendMutation(&mutation{Kind: mutationIssue, Repo: repo, Issue: &issue})
+}
+
+// UpsertPullRequest applies a pull request mutation for repo, keyed by PR number.
+func (c *Corpus) UpsertPullRequest(repo string, pr github.PullRequest) error {
+	return c.appendMutation(&mutation{Kind: mutationPullRequest, Repo: repo, PullRequest: &pr})
+}
+
+// UpsertCommit applies a commit mutation for repo, keyed by SHA.
+func (c *Corpus) UpsertCommit(repo string, commit github.RepositoryCommit) error {
+	return c.appendMutation(&mutation{Kind: mutationCommit, Repo: repo, Commit: &commit})
+}
+
+// Commit returns the commit stored for repo/sha, if any, so a caller can
+// merge into an already-synced record instead of blindly overwriting it.
+func (c *Corpus) Commit(repo, sha string) (github.RepositoryCommit, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	commit, ok := c.commits[repo][sha]
+	return commit, ok
+}
+
+// ForeachRepo calls fn for every known repository, stopping early if fn
+// returns false.
+func (c *Corpus) ForeachRepo(fn func(github.Repository) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, repo := range c.repos {
+		if !fn(repo) {
+			return
+		}
+	}
+}
+
+// ForeachIssue calls fn for every known issue in repo, stopping early if fn
+// returns false.
+func (c *Corpus) ForeachIssue(repo string, fn func(github.Issue) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, issue := range c.issues[repo] {
+		if !fn(issue) {
+			return
+		}
+	}
+}
+
+// ForeachPullRequest calls fn for every known pull request in repo, stopping
+// early if fn returns false.
+func (c *Corpus) ForeachPullRequest(repo string, fn func(github.PullRequest) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, pr := range c.pullRequests[repo] {
+		if !fn(pr) {
+			return
+		}
+	}
+}
+
+// ForeachCommit calls fn for every known commit in repo, stopping early if fn
+// returns false.
+func (c *Corpus) ForeachCommit(repo string, fn func(github.RepositoryCommit) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, commit := range c.commits[repo] {
+		if !fn(commit) {
+			return
+		}
+	}
+}
+
+// HasRepo reports whether repo has ever been synced into the corpus.
+func (c *Corpus) HasRepo(repo string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.sinceIssue[repo]
+	return ok
+}
+
+// Sync performs an initial full sync of owner/repo: every open and closed
+// issue, pull request and commit is fetched and applied as a mutation. Call
+// Update afterwards to catch up incrementally.
+func (c *Corpus) Sync(ctx context.Context, owner, repo string) error {
+	full := owner + "/" + repo
+
+	if err := c.syncIssues(ctx, owner, repo, time.Time{}); err != nil {
+		return err
+	}
+	if err := c.syncPullRequests(ctx, owner, repo, time.Time{}); err != nil {
+		return err
+	}
+	if err := c.syncCommits(ctx, owner, repo, time.Time{}); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return c.recordSince(full, now, now, now)
+}
+
+// Update fetches everything that changed since the last Sync/Update for
+// every repo the corpus already knows about. Issues and commits are requested
+// with GitHub's Since parameter; pull requests, whose list call has no such
+// parameter, are listed by update time and paging stops at the first one
+// older than the last sync.
+func (c *Corpus) Update(ctx context.Context) error {
+	c.mu.RLock()
+	repos := make([]string, 0, len(c.sinceIssue))
+	for repo := range c.sinceIssue {
+		repos = append(repos, repo)
+	}
+	c.mu.RUnlock()
+
+	for _, full := range repos {
+		owner, repo := splitRepo(full)
+
+		c.mu.RLock()
+		sinceIssue := c.sinceIssue[full]
+		sincePR := c.sincePR[full]
+		sinceCommit := c.sinceCommit[full]
+		c.mu.RUnlock()
+
+		now := time.Now()
+
+		if err := c.syncIssues(ctx, owner, repo, sinceIssue); err != nil {
+			return err
+		}
+		if err := c.syncPullRequests(ctx, owner, repo, sincePR); err != nil {
+			return err
+		}
+		if err := c.syncCommits(ctx, owner, repo, sinceCommit); err != nil {
+			return err
+		}
+
+		if err := c.recordSince(full, now, now, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Corpus) syncIssues(ctx context.Context, owner, repo string, since time.Time) error {
+	full := owner + "/" + repo
+	opt := &github.IssueListByRepoOptions{
+		State:       "all",
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		issues, resp, err := c.client.Issues.ListByRepo(ctx, owner, repo, opt)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			if err := c.UpsertIssue(full, issue); err != nil {
+				return err
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	return nil
+}
+
+func (c *Corpus) syncPullRequests(ctx context.Context, owner, repo string, since time.Time) error {
+	full := owner + "/" + repo
+	opt := &github.PullRequestListOptions{
+		State:       "all",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pullRequests, resp, err := c.client.PullRequests.List(ctx, owner, repo, opt)
+		if err != nil {
+			return err
+		}
+
+		stop := false
+		for _, pr := range pullRequests {
+			if !since.IsZero() && pr.UpdatedAt != nil && pr.UpdatedAt.Before(since) {
+				// PRs are sorted by update time descending, so once we hit one
+				// older than the last sync, everything after it is too.
+				stop = true
+				break
+			}
+			if err := c.UpsertPullRequest(full, pr); err != nil {
+				return err
+			}
+		}
+
+		if resp.NextPage == 0 || stop {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	return nil
+}
+
+func (c *Corpus) syncCommits(ctx context.Context, owner, repo string, since time.Time) error {
+	full := owner + "/" + repo
+	opt := &github.CommitsListOptions{
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		commits, resp, err := c.client.Repositories.ListCommits(ctx, owner, repo, opt)
+		if err != nil {
+			return err
+		}
+
+		for _, commit := range commits {
+			if err := c.UpsertCommit(full, commit); err != nil {
+				return err
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	return nil
+}
+
+func splitRepo(full string) (owner, repo string) {
+	for i := 0; i < len(full); i++ {
+		if full[i] == '/' {
+			return full[:i], full[i+1:]
+		}
+	}
+	return full, ""
+}
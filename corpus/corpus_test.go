@@ -0,0 +1,59 @@
+package corpus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// TestReplayAcrossMultipleMutations guards against a framing bug where a
+// second on-disk mutation failed to decode: each appendMutation call used a
+// fresh gob.Encoder (re-sending gob's type preamble), while replay read the
+// whole log with a single gob.Decoder, which rejected the second record with
+// "gob: duplicate type received".
+func TestReplayAcrossMultipleMutations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "corpus-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "corpus.log")
+
+	c, err := New(nil, logPath)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	const repo = "owner/repo"
+	for i := 1; i <= 5; i++ {
+		issue := github.Issue{Number: github.Int(i)}
+		if err := c.UpsertIssue(repo, issue); err != nil {
+			t.Fatalf("UpsertIssue #%d: %v", i, err)
+		}
+	}
+	if err := c.recordSince(repo, c.sinceIssue[repo], c.sincePR[repo], c.sinceCommit[repo]); err != nil {
+		t.Fatalf("recordSince: %v", err)
+	}
+
+	reloaded, err := New(nil, logPath)
+	if err != nil {
+		t.Fatalf("New on reload: %v", err)
+	}
+
+	var count int
+	reloaded.ForeachIssue(repo, func(github.Issue) bool {
+		count++
+		return true
+	})
+	if count != 5 {
+		t.Fatalf("got %d issues after reload, want 5", count)
+	}
+
+	if !reloaded.HasRepo(repo) {
+		t.Fatal("HasRepo false after reload, want true (since-timestamps should survive restart)")
+	}
+}
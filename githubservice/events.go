@@ -0,0 +1,153 @@
+package githubservice
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/net/context"
+)
+
+// Event describes a webhook-driven change that was just applied to Corpus,
+// so a caller can push-update a dashboard instead of polling for changes.
+type Event struct {
+	Type string // "issues", "issue_comment", "pull_request", "push" or "label"
+	Repo string // "owner/repo"
+}
+
+// ApplyEvent decodes a GitHub webhook payload of the given eventType (the
+// value of the X-GitHub-Event header) and mutates Corpus accordingly,
+// upserting by issue number / PR number / commit SHA. It requires Corpus to
+// be set. On success it notifies Updates, if set, without blocking.
+func (g *GithubService) ApplyEvent(ctx context.Context, eventType string, payload []byte) error {
+	if g.Corpus == nil {
+		return fmt.Errorf("githubservice: ApplyEvent requires Corpus to be configured")
+	}
+
+	var repo string
+	var err error
+
+	switch eventType {
+	case "issues":
+		repo, err = g.applyIssuesEvent(payload)
+	case "issue_comment":
+		repo, err = g.applyIssueCommentEvent(payload)
+	case "pull_request":
+		repo, err = g.applyPullRequestEvent(payload)
+	case "push":
+		repo, err = g.applyPushEvent(payload)
+	case "label":
+		repo, err = g.applyLabelEvent(payload)
+	default:
+		return fmt.Errorf("githubservice: unsupported webhook event type %q", eventType)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	g.notify(Event{Type: eventType, Repo: repo})
+	return nil
+}
+
+func (g *GithubService) notify(event Event) {
+	if g.Updates == nil {
+		return
+	}
+	select {
+	case g.Updates <- event:
+	default:
+		// Slow or absent consumer: drop rather than block event ingestion.
+	}
+}
+
+func (g *GithubService) applyIssuesEvent(payload []byte) (string, error) {
+	var e github.IssuesEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return "", err
+	}
+	if e.Repo == nil || e.Repo.FullName == nil || e.Issue == nil {
+		return "", fmt.Errorf("githubservice: issues event missing repo or issue")
+	}
+	return *e.Repo.FullName, g.Corpus.UpsertIssue(*e.Repo.FullName, *e.Issue)
+}
+
+func (g *GithubService) applyIssueCommentEvent(payload []byte) (string, error) {
+	var e github.IssueCommentEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return "", err
+	}
+	if e.Repo == nil || e.Repo.FullName == nil || e.Issue == nil {
+		return "", fmt.Errorf("githubservice: issue_comment event missing repo or issue")
+	}
+	return *e.Repo.FullName, g.Corpus.UpsertIssue(*e.Repo.FullName, *e.Issue)
+}
+
+func (g *GithubService) applyPullRequestEvent(payload []byte) (string, error) {
+	var e github.PullRequestEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return "", err
+	}
+	if e.Repo == nil || e.Repo.FullName == nil || e.PullRequest == nil {
+		return "", fmt.Errorf("githubservice: pull_request event missing repo or pull request")
+	}
+	return *e.Repo.FullName, g.Corpus.UpsertPullRequest(*e.Repo.FullName, *e.PullRequest)
+}
+
+func (g *GithubService) applyPushEvent(payload []byte) (string, error) {
+	var e github.PushEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return "", err
+	}
+	if e.Repo == nil || e.Repo.FullName == nil {
+		return "", fmt.Errorf("githubservice: push event missing repo")
+	}
+
+	full := *e.Repo.FullName
+	for _, pushCommit := range e.Commits {
+		if pushCommit.SHA == nil {
+			continue
+		}
+
+		// The push payload's commit entries don't carry a commit date (only
+		// author/committer name+email), so Committer.Date is left unset here.
+		// masterCommitsForSingleRepo's corpus-branch date filter requires it,
+		// so these commits won't surface in a date-windowed view until the
+		// next Sync/Update backfills the full commit from the API.
+		commit := github.RepositoryCommit{
+			SHA: pushCommit.SHA,
+			Commit: &github.Commit{
+				Message:   pushCommit.Message,
+				Committer: pushCommit.Committer,
+			},
+		}
+
+		// If a prior Sync/Update already stored a fuller record for this SHA
+		// (Author, Stats, Files, a real Committer.Date), don't regress it to
+		// this stripped push-event version; a redelivered or overlapping
+		// webhook would otherwise silently wipe that data.
+		if _, ok := g.Corpus.Commit(full, *pushCommit.SHA); ok {
+			continue
+		}
+
+		if err := g.Corpus.UpsertCommit(full, commit); err != nil {
+			return "", err
+		}
+	}
+
+	return full, nil
+}
+
+func (g *GithubService) applyLabelEvent(payload []byte) (string, error) {
+	var e github.LabelEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return "", err
+	}
+	if e.Repo == nil || e.Repo.FullName == nil {
+		return "", fmt.Errorf("githubservice: label event missing repo")
+	}
+	// Labels aren't indexed by the corpus directly; re-synced issues/PRs pick
+	// up the change on their next Update. We still notify so a dashboard can
+	// re-render with the latest taxonomy.
+	return *e.Repo.FullName, nil
+}
@@ -2,21 +2,87 @@ package githubservice
 
 import (
 	"github.com/google/go-github/github"
+	"github.com/valentim/marvin/corpus"
+	"github.com/valentim/marvin/httpcache"
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 	"log"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
+// githubAuthenticatedRateLimit is GitHub's default hourly ceiling for
+// authenticated requests; it seeds the limiter until the first response
+// tells us the real remaining/reset values.
+const githubAuthenticatedRateLimit = 5000
+
+// defaultConcurrency bounds org-wide fan-out when GithubService.Concurrency
+// is left unset.
+const defaultConcurrency = 8
+
 type GithubService struct {
 	PersonalAccessToken string
+
+	// Corpus, if set, is consulted before falling back to the live API so
+	// that repeated reads don't re-paginate GitHub on every call. Populate it
+	// with corpus.New and keep it in sync with its Sync/Update methods.
+	Corpus *corpus.Corpus
+
+	// Cache, if set, is used to serve conditional requests out of a cached
+	// 304 instead of re-fetching unchanged objects. Defaults to an in-memory
+	// cache; set to a httpcache.DiskCache to persist across restarts.
+	Cache httpcache.Cache
+
+	// Concurrency bounds how many repos/PRs are fetched in parallel during
+	// org-wide fan-out. Defaults to defaultConcurrency when <= 0.
+	Concurrency int
+
+	// Updates, if set, receives an Event after every webhook-driven mutation
+	// applied by ApplyEvent, so a caller can push-update a dashboard instead
+	// of polling. Sends are non-blocking; a full or nil channel just drops.
+	Updates chan Event
+
+	// Taxonomy resolves which workflow column an issue belongs to. Set by
+	// New; pass nil there to fall back to DefaultLabelTaxonomy.
+	Taxonomy *LabelTaxonomy
+
+	limiterOnce sync.Once
+	limiter     *rate.Limiter
+}
+
+// rateLimiter returns the rate limiter shared by every authenticated client this
+// GithubService builds, lazily initializing it on first use. obtainAuthenticatedGithubClient
+// is called per-request (including concurrently, during org-wide fan-out), so a
+// limiter constructed fresh each time wouldn't actually bound anything.
+func (g *GithubService) rateLimiter() *rate.Limiter {
+	g.limiterOnce.Do(func() {
+		g.limiter = rate.NewLimiter(rate.Limit(githubAuthenticatedRateLimit/3600.0), 100)
+	})
+	return g.limiter
 }
 
-func New(personalAccessToken string) *GithubService {
+func (g *GithubService) concurrency() int {
+	if g.Concurrency > 0 {
+		return g.Concurrency
+	}
+	return defaultConcurrency
+}
+
+// New returns a GithubService authenticated with personalAccessToken. If
+// taxonomy is nil, DefaultLabelTaxonomy is used, reproducing Marvin's
+// original hardcoded columns.
+func New(personalAccessToken string, taxonomy *LabelTaxonomy) *GithubService {
+	if taxonomy == nil {
+		taxonomy = DefaultLabelTaxonomy()
+	}
 	g := GithubService{
 		PersonalAccessToken: personalAccessToken,
+		Cache:               httpcache.NewMemoryCache(),
+		Taxonomy:            taxonomy,
 	}
 	return &g
 }
@@ -32,29 +98,43 @@ func (t *TokenSource) Token() (*oauth2.Token, error) {
 	return token, nil
 }
 
-func (g *GithubService) obtainAuthenticatedGithubClient() (c *github.Client) {
+func (g *GithubService) obtainAuthenticatedGithubClient(ctx context.Context) (c *github.Client) {
 	tokenSource := &TokenSource{
 		AccessToken: g.PersonalAccessToken,
 	}
-	oauthClient := oauth2.NewClient(context.TODO(), tokenSource)
+	oauthClient := oauth2.NewClient(ctx, tokenSource)
+
+	cache := g.Cache
+	if cache == nil {
+		cache = httpcache.NewMemoryCache()
+	}
+
+	oauthClient.Transport = httpcache.New(
+		httpcache.WithTransport(oauthClient.Transport),
+		httpcache.WithCache(cache),
+		httpcache.WithLimiter(g.rateLimiter()),
+	)
+
 	return github.NewClient(oauthClient)
 }
 
-func (g *GithubService) loadIssuesForAssignee(owner string, assignee string) ([]github.Issue, error) {
-	var client = g.obtainAuthenticatedGithubClient()
+func (g *GithubService) loadIssuesForAssignee(ctx context.Context, owner string, assignee string) ([]github.Issue, error) {
+	var client = g.obtainAuthenticatedGithubClient(ctx)
 	var all []github.Issue
-	var e error
 	opt := &github.SearchOptions{
 
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
 	for {
-		issueSearchResults, resp, err := client.Search.Issues("user:"+owner+" assignee:"+assignee, opt)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		issueSearchResults, resp, err := client.Search.Issues(ctx, "user:"+owner+" assignee:"+assignee, opt)
 
 		if err != nil {
-			e = err
-			break
+			return nil, err
 		}
 
 		all = append(all, issueSearchResults.Issues...)
@@ -66,24 +146,26 @@ func (g *GithubService) loadIssuesForAssignee(owner string, assignee string) ([]
 		opt.ListOptions.Page = resp.NextPage
 	}
 
-	return all, e
+	return all, nil
 }
 
-func (g *GithubService) loadIssuesForRepo(owner string, repo string, assigned string) ([]github.Issue, error) {
-	var client = g.obtainAuthenticatedGithubClient()
+func (g *GithubService) loadIssuesForRepo(ctx context.Context, owner string, repo string, assigned string) ([]github.Issue, error) {
+	var client = g.obtainAuthenticatedGithubClient(ctx)
 	var allIssues []github.Issue
-	var e error
 	opt := &github.IssueListByRepoOptions{
 		Assignee:    assigned,
 		ListOptions: github.ListOptions{PerPage: 500},
 	}
 
 	for {
-		issues, resp, err := client.Issues.ListByRepo(owner, repo, opt)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		issues, resp, err := client.Issues.ListByRepo(ctx, owner, repo, opt)
 
 		if err != nil {
-			e = err
-			break
+			return nil, err
 		}
 
 		allIssues = append(allIssues, issues...)
@@ -95,24 +177,26 @@ func (g *GithubService) loadIssuesForRepo(owner string, repo string, assigned st
 		opt.ListOptions.Page = resp.NextPage
 	}
 
-	return allIssues, e
+	return allIssues, nil
 }
 
-func (g *GithubService) loadCommitsForRepo(owner string, repo string, committer string, timeLimit time.Time) ([]github.RepositoryCommit, error) {
-	var client = g.obtainAuthenticatedGithubClient()
+func (g *GithubService) loadCommitsForRepo(ctx context.Context, owner string, repo string, committer string, timeLimit time.Time) ([]github.RepositoryCommit, error) {
+	var client = g.obtainAuthenticatedGithubClient(ctx)
 	var allCommits []github.RepositoryCommit
-	var e error
 	opt := &github.CommitsListOptions{
 		Since:       timeLimit,
 		ListOptions: github.ListOptions{PerPage: 500},
 	}
 
 	for {
-		repositoryCommits, resp, err := client.Repositories.ListCommits(owner, repo, opt)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		repositoryCommits, resp, err := client.Repositories.ListCommits(ctx, owner, repo, opt)
 
 		if err != nil {
-			e = err
-			break
+			return nil, err
 		}
 
 		allCommits = append(allCommits, repositoryCommits...)
@@ -124,24 +208,26 @@ func (g *GithubService) loadCommitsForRepo(owner string, repo string, committer
 		opt.ListOptions.Page = resp.NextPage
 	}
 
-	return allCommits, e
+	return allCommits, nil
 }
 
-func (g *GithubService) loadReposForOrganization(owner string) ([]github.Repository, error) {
-	var client = g.obtainAuthenticatedGithubClient()
+func (g *GithubService) loadReposForOrganization(ctx context.Context, owner string) ([]github.Repository, error) {
+	var client = g.obtainAuthenticatedGithubClient(ctx)
 	var allRepos []github.Repository
-	var e error
 	opt := &github.RepositoryListByOrgOptions{
 		Type:        "all",
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
 	for {
-		repos, resp, err := client.Repositories.ListByOrg(owner, opt)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		repos, resp, err := client.Repositories.ListByOrg(ctx, owner, opt)
 
 		if err != nil {
-			e = err
-			break
+			return nil, err
 		}
 
 		allRepos = append(allRepos, repos...)
@@ -153,13 +239,12 @@ func (g *GithubService) loadReposForOrganization(owner string) ([]github.Reposit
 		opt.ListOptions.Page = resp.NextPage
 	}
 
-	return allRepos, e
+	return allRepos, nil
 }
 
-func (g *GithubService) loadPRsForRepo(owner string, repo string) ([]github.PullRequest, error) {
-	var client = g.obtainAuthenticatedGithubClient()
+func (g *GithubService) loadPRsForRepo(ctx context.Context, owner string, repo string) ([]github.PullRequest, error) {
+	var client = g.obtainAuthenticatedGithubClient(ctx)
 	var allPRs []github.PullRequest
-	var e error
 	opt := &github.PullRequestListOptions{
 		State: "open",
 		// TODO: These params should be available but sadly they don't pass the compiler
@@ -169,10 +254,13 @@ func (g *GithubService) loadPRsForRepo(owner string, repo string) ([]github.Pull
 	}
 
 	for {
-		pullRequests, resp, err := client.PullRequests.List(owner, repo, opt)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		pullRequests, resp, err := client.PullRequests.List(ctx, owner, repo, opt)
 		if err != nil {
-			e = err
-			break
+			return nil, err
 		}
 
 		allPRs = append(allPRs, pullRequests...)
@@ -184,13 +272,11 @@ func (g *GithubService) loadPRsForRepo(owner string, repo string) ([]github.Pull
 		opt.ListOptions.Page = resp.NextPage
 	}
 
-	return allPRs, e
+	return allPRs, nil
 }
 
-func (g *GithubService) loadCommitsFromAllRepoPRs(owner string, repo string, timeLimit time.Time) ([]github.RepositoryCommit, error) {
-	var client = g.obtainAuthenticatedGithubClient()
-	var allPRCommits []github.RepositoryCommit
-	var e error
+func (g *GithubService) loadCommitsFromAllRepoPRs(ctx context.Context, owner string, repo string, timeLimit time.Time) ([]github.RepositoryCommit, error) {
+	var client = g.obtainAuthenticatedGithubClient(ctx)
 	opt := &github.PullRequestListOptions{
 		State:       "open,closed",
 		Sort:        "updated",
@@ -198,62 +284,103 @@ func (g *GithubService) loadCommitsFromAllRepoPRs(owner string, repo string, tim
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
+	var prsInWindow []github.PullRequest
 	remainingPRsAreOlder := false
 
 	for {
-		pullRequests, resp, err := client.PullRequests.List(owner, repo, opt)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		pullRequests, resp, err := client.PullRequests.List(ctx, owner, repo, opt)
 		if err != nil {
-			e = err
-			break
+			return nil, err
 		}
 
 		for _, pullRequest := range pullRequests {
-
 			if timeLimit.After(*pullRequest.CreatedAt) && timeLimit.After(*pullRequest.UpdatedAt) {
 				//PR is older than time box. Assuming a sorted list it is safe to stop processing.
 				remainingPRsAreOlder = true
 				break
 			}
 
-			prOpt := &github.ListOptions{
-				PerPage: 100,
+			prsInWindow = append(prsInWindow, pullRequest)
+		}
+
+		if resp.NextPage == 0 || remainingPRsAreOlder {
+			break
+		}
+
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	var mu sync.Mutex
+	var allPRCommits []github.RepositoryCommit
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, g.concurrency())
+
+	for _, pullRequest := range prsInWindow {
+		pullRequest := pullRequest
+		group.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-groupCtx.Done():
+				return groupCtx.Err()
 			}
-			prCommits, prResp, err := client.PullRequests.ListCommits(owner, repo, *pullRequest.Number, prOpt)
+			defer func() { <-sem }()
 
+			prCommits, err := g.loadCommitsForPR(groupCtx, owner, repo, *pullRequest.Number)
 			if err != nil {
-				e = err
-				continue
+				return err
 			}
 
-			for _, prCommit := range prCommits {
-				allPRCommits = append(allPRCommits, prCommit)
-			}
+			mu.Lock()
+			allPRCommits = append(allPRCommits, prCommits...)
+			mu.Unlock()
+			return nil
+		})
+	}
 
-			if prResp.NextPage == 0 {
-				continue
-			}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return allPRCommits, nil
+}
 
-			prOpt.Page = prResp.NextPage
+func (g *GithubService) loadCommitsForPR(ctx context.Context, owner string, repo string, number int) ([]github.RepositoryCommit, error) {
+	var client = g.obtainAuthenticatedGithubClient(ctx)
+	var allPRCommits []github.RepositoryCommit
+	opt := &github.ListOptions{PerPage: 100}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		if resp.NextPage == 0 || remainingPRsAreOlder {
-			break
+		prCommits, resp, err := client.PullRequests.ListCommits(ctx, owner, repo, number, opt)
+		if err != nil {
+			return nil, err
 		}
 
-		opt.ListOptions.Page = resp.NextPage
+		allPRCommits = append(allPRCommits, prCommits...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
 	}
 
-	return allPRCommits, e
+	return allPRCommits, nil
 }
 
-func (g *GithubService) loadActiveReposForOrganization(owner string, days int) ([]github.Repository, error) {
-	var allRepos []github.Repository
+func (g *GithubService) loadActiveReposForOrganization(ctx context.Context, owner string, days int) ([]github.Repository, error) {
 	var activeRepos []github.Repository
-	var e error
 
-	allRepos, err := g.loadReposForOrganization(owner)
+	allRepos, err := g.loadReposForOrganization(ctx, owner)
 	if err != nil {
-		e = err
+		return nil, err
 	}
 
 	for _, repo := range allRepos {
@@ -263,41 +390,61 @@ func (g *GithubService) loadActiveReposForOrganization(owner string, days int) (
 	}
 	sort.Sort(RepositoryNameSorter(activeRepos))
 
-	return activeRepos, e
+	return activeRepos, nil
 }
 
-func (g *GithubService) loadOpenPRsForOrganization(owner string, daysPROpen int, daysSinceLastProjectActivity int) ([]github.PullRequest, error) {
-	var activeRepos []github.Repository
-	var e error
-
-	activeRepos, err := g.loadActiveReposForOrganization(owner, daysSinceLastProjectActivity)
+func (g *GithubService) loadOpenPRsForOrganization(ctx context.Context, owner string, daysPROpen int, daysSinceLastProjectActivity int) ([]github.PullRequest, error) {
+	activeRepos, err := g.loadActiveReposForOrganization(ctx, owner, daysSinceLastProjectActivity)
 	if err != nil {
-		e = err
+		return nil, err
 	}
 
+	var mu sync.Mutex
 	var allOpenPRs []github.PullRequest
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, g.concurrency())
+
 	for _, repo := range activeRepos {
-		pullRequests, err := g.loadPRsForRepo(owner, *repo.Name)
-		if err != nil {
-			e = err
-			break
-		}
+		repo := repo
+		group.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-groupCtx.Done():
+				return groupCtx.Err()
+			}
+			defer func() { <-sem }()
 
-		for _, pullRequest := range pullRequests {
-			var numberOfDays = time.Since(*pullRequest.CreatedAt).Hours() / 24
-			if numberOfDays < float64(daysPROpen) {
-				log.Printf("Skipping PR open for %f days", numberOfDays)
-				break // These PRs are too new for us to care about
-			} else {
+			pullRequests, err := g.loadPRsForRepo(groupCtx, owner, *repo.Name)
+			if err != nil {
+				return err
+			}
+
+			var repoOpenPRs []github.PullRequest
+			for _, pullRequest := range pullRequests {
+				var numberOfDays = time.Since(*pullRequest.CreatedAt).Hours() / 24
+				if numberOfDays < float64(daysPROpen) {
+					log.Printf("Skipping PR open for %f days", numberOfDays)
+					break // These PRs are too new for us to care about
+				}
 				log.Printf("Adding PR open for %f days", numberOfDays)
-				allOpenPRs = append(allOpenPRs, pullRequest)
+				repoOpenPRs = append(repoOpenPRs, pullRequest)
 			}
-		}
+
+			mu.Lock()
+			allOpenPRs = append(allOpenPRs, repoOpenPRs...)
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
 	}
 
 	sort.Sort(PROpenDurationSorter(allOpenPRs))
 
-	return allOpenPRs, e
+	return allOpenPRs, nil
 }
 
 // RepositoryNameSorter sorts Repository by name.
@@ -316,12 +463,28 @@ func (a PROpenDurationSorter) Len() int           { return len(a) }
 func (a PROpenDurationSorter) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a PROpenDurationSorter) Less(i, j int) bool { return (*a[i].CreatedAt).Before(*a[j].CreatedAt) }
 
-func (g *GithubService) makeIssueList(owner string, repo string, assigned string, lambda func(github.Issue) bool) ([]github.Issue, error) {
+func (g *GithubService) makeIssueList(ctx context.Context, owner string, repo string, assigned string, lambda func(github.Issue) bool) ([]github.Issue, error) {
 
-	issues, err := g.loadIssuesForRepo(owner, repo, assigned)
+	var issues []github.Issue
 
-	if err != nil {
-		return nil, err
+	if g.Corpus != nil && g.Corpus.HasRepo(owner+"/"+repo) {
+		g.Corpus.ForeachIssue(owner+"/"+repo, func(issue github.Issue) bool {
+			// The corpus is synced with State: "all", but loadIssuesForRepo's
+			// live path defaults to open-only; filter here so both paths agree.
+			if issue.State != nil && *issue.State != "open" {
+				return true
+			}
+			if assigned == "" || (issue.Assignee != nil && issue.Assignee.Login != nil && *issue.Assignee.Login == assigned) {
+				issues = append(issues, issue)
+			}
+			return true
+		})
+	} else {
+		var err error
+		issues, err = g.loadIssuesForRepo(ctx, owner, repo, assigned)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	var sprintIssues []github.Issue
@@ -336,55 +499,90 @@ func (g *GithubService) makeIssueList(owner string, repo string, assigned string
 		}
 	}
 
-	return sprintIssues, err
+	return sprintIssues, nil
 }
 
-func (g *GithubService) makeCommitsList(owner string, repo string, committer string, lambda func(github.RepositoryCommit, []github.RepositoryCommit) bool, days int) (map[string][]github.RepositoryCommit, int, error) {
+func (g *GithubService) makeCommitsList(ctx context.Context, owner string, repo string, committer string, lambda func(github.RepositoryCommit, []github.RepositoryCommit) bool, days int) (map[string][]github.RepositoryCommit, int, error) {
 
-	totalCommits := 0
-	repoToMasterCommits := make(map[string][]github.RepositoryCommit)
-
-	if repo == "" {
-		//summary of commits from all repos
-		repositories, err := g.loadActiveReposForOrganization(owner, days)
+	if repo != "" {
+		//single repo query
+		masterCommits, totalRepoCommits, err := g.masterCommitsForSingleRepo(ctx, owner, repo, committer, lambda, days)
 		if err != nil {
 			return nil, 0, err
 		}
 
-		for _, repository := range repositories {
-			repoName := *repository.Name
-			masterCommits, totalRepoCommits, err := g.masterCommitsForSingleRepo(owner, repoName, committer, lambda, days)
+		return map[string][]github.RepositoryCommit{repo: masterCommits}, totalRepoCommits, nil
+	}
 
+	//summary of commits from all repos
+	repositories, err := g.loadActiveReposForOrganization(ctx, owner, days)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var mu sync.Mutex
+	totalCommits := 0
+	repoToMasterCommits := make(map[string][]github.RepositoryCommit)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, g.concurrency())
+
+	for _, repository := range repositories {
+		repository := repository
+		group.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-groupCtx.Done():
+				return groupCtx.Err()
+			}
+			defer func() { <-sem }()
+
+			repoName := *repository.Name
+			masterCommits, totalRepoCommits, err := g.masterCommitsForSingleRepo(groupCtx, owner, repoName, committer, lambda, days)
 			if err != nil {
-				return nil, 0, err
+				return err
 			}
 
 			if len(masterCommits) > 0 {
+				mu.Lock()
 				repoToMasterCommits[repoName] = masterCommits
 				totalCommits += totalRepoCommits
+				mu.Unlock()
 			}
-		}
-	} else {
-		//single repo query
-		masterCommits, totalRepoCommits, err := g.masterCommitsForSingleRepo(owner, repo, committer, lambda, days)
-
-		if err != nil {
-			return nil, 0, err
-		}
+			return nil
+		})
+	}
 
-		repoToMasterCommits[repo] = masterCommits
-		totalCommits += totalRepoCommits
+	if err := group.Wait(); err != nil {
+		return nil, 0, err
 	}
 
 	return repoToMasterCommits, totalCommits, nil
 }
 
-func (g *GithubService) masterCommitsForSingleRepo(owner string, repo string, committer string, lambda func(github.RepositoryCommit, []github.RepositoryCommit) bool, days int) ([]github.RepositoryCommit, int, error) {
+func (g *GithubService) masterCommitsForSingleRepo(ctx context.Context, owner string, repo string, committer string, lambda func(github.RepositoryCommit, []github.RepositoryCommit) bool, days int) ([]github.RepositoryCommit, int, error) {
 
 	var timeLimit = time.Now().AddDate(0, 0, -days)
 
-	commits, err := g.loadCommitsForRepo(owner, repo, committer, timeLimit)
-	allPRCommits, err := g.loadCommitsFromAllRepoPRs(owner, repo, timeLimit)
+	var commits []github.RepositoryCommit
+	var allPRCommits []github.RepositoryCommit
+	var err error
+
+	if g.Corpus != nil && g.Corpus.HasRepo(owner+"/"+repo) {
+		g.Corpus.ForeachCommit(owner+"/"+repo, func(commit github.RepositoryCommit) bool {
+			if commit.Commit != nil && commit.Commit.Committer != nil && commit.Commit.Committer.Date != nil && commit.Commit.Committer.Date.After(timeLimit) {
+				commits = append(commits, commit)
+			}
+			return true
+		})
+		allPRCommits, err = g.loadCommitsFromAllRepoPRs(ctx, owner, repo, timeLimit)
+	} else {
+		commits, err = g.loadCommitsForRepo(ctx, owner, repo, committer, timeLimit)
+		if err != nil {
+			return nil, 0, err
+		}
+		allPRCommits, err = g.loadCommitsFromAllRepoPRs(ctx, owner, repo, timeLimit)
+	}
 
 	if err != nil {
 		return nil, 0, err
@@ -400,106 +598,63 @@ func (g *GithubService) masterCommitsForSingleRepo(owner string, repo string, co
 		}
 	}
 
-	return masterCommits, len(commits), err
+	return masterCommits, len(commits), nil
 }
 
-func (g *GithubService) AssignedTo(owner string, repo string, login string) ([]github.Issue, error) {
+func (g *GithubService) AssignedTo(ctx context.Context, owner string, repo string, login string) ([]github.Issue, error) {
 	if repo == "*" {
-		return g.loadIssuesForAssignee(owner, login)
+		return g.loadIssuesForAssignee(ctx, owner, login)
 
 	} else {
-		return g.makeIssueList(owner, repo, login, g.any)
+		return g.makeIssueList(ctx, owner, repo, login, g.any)
 	}
 }
 
-func (g *GithubService) Sprint(owner string, repo string) ([]github.Issue, error) {
-	return g.makeIssueList(owner, repo, "", g.isSprintItem)
+// IssuesByColumn returns the issues in owner/repo that belong to column, as
+// resolved by Taxonomy. Sprint, InProgress, ReadyForQA, QAPass, Backlog and
+// ReadyForReview are thin wrappers kept for backward compatibility.
+func (g *GithubService) IssuesByColumn(ctx context.Context, owner string, repo string, column string) ([]github.Issue, error) {
+	return g.makeIssueList(ctx, owner, repo, "", func(issue github.Issue) bool {
+		return g.Taxonomy.Matches(issue, column)
+	})
 }
 
-func (g *GithubService) InProgress(owner string, repo string) ([]github.Issue, error) {
-	return g.makeIssueList(owner, repo, "", g.isInProgress)
+func (g *GithubService) Sprint(ctx context.Context, owner string, repo string) ([]github.Issue, error) {
+	return g.IssuesByColumn(ctx, owner, repo, "sprint")
 }
 
-func (g *GithubService) ReadyForQA(owner string, repo string) ([]github.Issue, error) {
-	return g.makeIssueList(owner, repo, "", g.isReadyForQA)
+func (g *GithubService) InProgress(ctx context.Context, owner string, repo string) ([]github.Issue, error) {
+	return g.IssuesByColumn(ctx, owner, repo, "in progress")
 }
 
-func (g *GithubService) QAPass(owner string, repo string) ([]github.Issue, error) {
-	return g.makeIssueList(owner, repo, "", g.isQAPass)
+func (g *GithubService) ReadyForQA(ctx context.Context, owner string, repo string) ([]github.Issue, error) {
+	return g.IssuesByColumn(ctx, owner, repo, "ready for qa")
 }
 
-func (g *GithubService) Backlog(owner string, repo string) ([]github.Issue, error) {
-	return g.makeIssueList(owner, repo, "", g.isBacklogItem)
+func (g *GithubService) QAPass(ctx context.Context, owner string, repo string) ([]github.Issue, error) {
+	return g.IssuesByColumn(ctx, owner, repo, "qa pass")
 }
 
-func (g *GithubService) ReadyForReview(owner string, repo string) ([]github.Issue, error) {
-	return g.makeIssueList(owner, repo, "", g.isReadyForReview)
+func (g *GithubService) Backlog(ctx context.Context, owner string, repo string) ([]github.Issue, error) {
+	return g.IssuesByColumn(ctx, owner, repo, "backlog")
 }
 
-func (g *GithubService) OpenPullRequests(owner string, daysPROpen int, daysSinceLastProjectActivity int) ([]github.PullRequest, error) {
-	return g.loadOpenPRsForOrganization(owner, daysPROpen, daysSinceLastProjectActivity)
+func (g *GithubService) ReadyForReview(ctx context.Context, owner string, repo string) ([]github.Issue, error) {
+	return g.IssuesByColumn(ctx, owner, repo, "ready for review")
 }
 
-func (g *GithubService) CommitsToMaster(owner string, repo string, days int) (map[string][]github.RepositoryCommit, int, error) {
-	return g.makeCommitsList(owner, repo, "", g.isCommitInList, days)
+func (g *GithubService) OpenPullRequests(ctx context.Context, owner string, daysPROpen int, daysSinceLastProjectActivity int) ([]github.PullRequest, error) {
+	return g.loadOpenPRsForOrganization(ctx, owner, daysPROpen, daysSinceLastProjectActivity)
 }
 
-func (g *GithubService) getLabelString(labels []github.Label) string {
-	var retval string
-	for _, label := range labels {
-		retval += strings.ToLower(*label.Name) + " "
-	}
-	return retval
+func (g *GithubService) CommitsToMaster(ctx context.Context, owner string, repo string, days int) (map[string][]github.RepositoryCommit, int, error) {
+	return g.makeCommitsList(ctx, owner, repo, "", g.isCommitInList, days)
 }
 
 func (g *GithubService) any(issue github.Issue) bool {
 	return true
 }
 
-func (g *GithubService) isBacklogItem(issue github.Issue) bool {
-
-	if g.isSprintItem(issue) || g.isInProgress(issue) || g.isReadyForQA(issue) || g.isQAPass(issue) || g.isDone(issue) {
-		return false
-	} else {
-		return true
-	}
-}
-
-func (g *GithubService) isProductBacklogItem(issue github.Issue) bool {
-	label := g.getLabelString(issue.Labels)
-	return strings.Contains(label, "product") && strings.Contains(label, "backlog")
-}
-
-func (g *GithubService) isSprintItem(issue github.Issue) bool {
-	label := g.getLabelString(issue.Labels)
-	return strings.Contains(label, "sprint")
-}
-
-func (g *GithubService) isInProgress(issue github.Issue) bool {
-	label := g.getLabelString(issue.Labels)
-	return strings.Contains(label, "in") && strings.Contains(label, "progress")
-}
-
-func (g *GithubService) isReadyForQA(issue github.Issue) bool {
-	label := g.getLabelString(issue.Labels)
-	return strings.Contains(label, "ready") && strings.Contains(label, "for") && strings.Contains(label, "qa")
-}
-
-func (g *GithubService) isReadyForReview(issue github.Issue) bool {
-	label := g.getLabelString(issue.Labels)
-	return strings.Contains(label, "ready") && strings.Contains(label, "for") && strings.Contains(label, "review")
-}
-
-func (g *GithubService) isQAPass(issue github.Issue) bool {
-	label := g.getLabelString(issue.Labels)
-	return strings.Contains(label, "qa") && strings.Contains(label, "pass")
-}
-
-func (g *GithubService) isDone(issue github.Issue) bool {
-	label := g.getLabelString(issue.Labels)
-	return strings.Contains(label, "done")
-}
-
 func (g *GithubService) isCommitInList(commit github.RepositoryCommit, commitList []github.RepositoryCommit) bool {
 
 	for _, listCommit := range commitList {
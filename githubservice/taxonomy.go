@@ -0,0 +1,185 @@
+package githubservice
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/github"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// MatchRule is a set of label-matching conditions that are all applied
+// together: the column's labels must contain every AllOf pattern, at least
+// one AnyOf pattern (when AnyOf is non-empty), and none of the NoneOf
+// patterns. Patterns are matched case-insensitively as substrings, or as
+// regular expressions when Regex is true.
+type MatchRule struct {
+	AllOf  []string `yaml:"allOf,omitempty" json:"allOf,omitempty"`
+	AnyOf  []string `yaml:"anyOf,omitempty" json:"anyOf,omitempty"`
+	NoneOf []string `yaml:"noneOf,omitempty" json:"noneOf,omitempty"`
+	Regex  bool     `yaml:"regex,omitempty" json:"regex,omitempty"`
+}
+
+// Column is one workflow column (e.g. "sprint", "in progress"). An issue
+// belongs to the column if it satisfies any one of Rules. ExcludesColumns,
+// when set, instead makes this a catch-all column that matches only when
+// none of the named columns match; it is mutually exclusive with Rules and
+// is how a taxonomy expresses a "backlog" bucket.
+type Column struct {
+	Name            string      `yaml:"name" json:"name"`
+	Rules           []MatchRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+	ExcludesColumns []string    `yaml:"excludesColumns,omitempty" json:"excludesColumns,omitempty"`
+}
+
+// LabelTaxonomy is a declarative, ordered set of workflow columns resolved
+// from issue labels, replacing the hardcoded "ready for qa" / "in progress"
+// substring-matchers so teams with a different label vocabulary can use
+// Marvin without forking.
+type LabelTaxonomy struct {
+	ColumnDefs []Column `yaml:"columns" json:"columns"`
+}
+
+// LoadLabelTaxonomy parses a taxonomy from YAML (JSON is valid YAML, so this
+// also accepts JSON documents).
+func LoadLabelTaxonomy(data []byte) (*LabelTaxonomy, error) {
+	var t LabelTaxonomy
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DefaultLabelTaxonomy reproduces Marvin's original hardcoded columns, for
+// callers that don't supply their own taxonomy.
+func DefaultLabelTaxonomy() *LabelTaxonomy {
+	return &LabelTaxonomy{
+		ColumnDefs: []Column{
+			{Name: "sprint", Rules: []MatchRule{{AnyOf: []string{"sprint"}}}},
+			{Name: "in progress", Rules: []MatchRule{{AllOf: []string{"in", "progress"}}}},
+			{Name: "ready for qa", Rules: []MatchRule{{AllOf: []string{"ready", "for", "qa"}}}},
+			{Name: "qa pass", Rules: []MatchRule{{AllOf: []string{"qa", "pass"}}}},
+			{Name: "ready for review", Rules: []MatchRule{{AllOf: []string{"ready", "for", "review"}}}},
+			{Name: "done", Rules: []MatchRule{{AnyOf: []string{"done"}}}},
+			{
+				Name:            "backlog",
+				ExcludesColumns: []string{"sprint", "in progress", "ready for qa", "qa pass", "done"},
+			},
+		},
+	}
+}
+
+// Columns returns the taxonomy's column names in order, so a caller can
+// build a board generically.
+func (t *LabelTaxonomy) Columns() []string {
+	names := make([]string, len(t.ColumnDefs))
+	for i, col := range t.ColumnDefs {
+		names[i] = col.Name
+	}
+	return names
+}
+
+func withVisited(visited map[string]bool, name string) map[string]bool {
+	next := make(map[string]bool, len(visited)+1)
+	for k := range visited {
+		next[k] = true
+	}
+	next[name] = true
+	return next
+}
+
+func (t *LabelTaxonomy) column(name string) *Column {
+	for i, col := range t.ColumnDefs {
+		if col.Name == name {
+			return &t.ColumnDefs[i]
+		}
+	}
+	return nil
+}
+
+// Matches reports whether issue belongs to the named column.
+func (t *LabelTaxonomy) Matches(issue github.Issue, columnName string) bool {
+	return t.matches(issue, columnName, nil)
+}
+
+func (t *LabelTaxonomy) matches(issue github.Issue, columnName string, visited map[string]bool) bool {
+	col := t.column(columnName)
+	if col == nil {
+		return false
+	}
+
+	if len(col.ExcludesColumns) > 0 {
+		if visited[columnName] {
+			// ExcludesColumns cycle in a misconfigured taxonomy; treat as
+			// non-matching rather than recursing forever.
+			return false
+		}
+		visited = withVisited(visited, columnName)
+
+		for _, other := range col.ExcludesColumns {
+			if t.matches(issue, other, visited) {
+				return false
+			}
+		}
+		return true
+	}
+
+	blob := labelBlob(issue.Labels)
+	for _, rule := range col.Rules {
+		if rule.matches(blob) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r MatchRule) matches(blob string) bool {
+	for _, pattern := range r.AllOf {
+		if !matchesPattern(blob, pattern, r.Regex) {
+			return false
+		}
+	}
+
+	if len(r.AnyOf) > 0 {
+		matched := false
+		for _, pattern := range r.AnyOf {
+			if matchesPattern(blob, pattern, r.Regex) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for _, pattern := range r.NoneOf {
+		if matchesPattern(blob, pattern, r.Regex) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesPattern(blob string, pattern string, isRegex bool) bool {
+	if isRegex {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(blob)
+	}
+	return strings.Contains(blob, strings.ToLower(pattern))
+}
+
+// labelBlob joins an issue's labels into a single lowercase, space-separated
+// string so rules can do cheap substring containment checks.
+func labelBlob(labels []github.Label) string {
+	var blob string
+	for _, label := range labels {
+		if label.Name != nil {
+			blob += strings.ToLower(*label.Name) + " "
+		}
+	}
+	return blob
+}
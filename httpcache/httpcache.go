@@ -0,0 +1,251 @@
+// Package httpcache provides an http.RoundTripper that caches GitHub API
+// responses and replays them on 304 Not Modified using the standard
+// ETag/If-None-Match and Last-Modified/If-Modified-Since conditional request
+// flow, and that rate-limits outgoing requests against GitHub's hourly quota.
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// FromCacheHeader is set on responses that were replayed from the cache
+// rather than fetched fresh, so callers can log or meter cache hits.
+const FromCacheHeader = "X-From-Cache"
+
+// Cache stores and retrieves raw, dumped HTTP responses keyed by request
+// identity (method + URL). Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (responseBytes []byte, ok bool)
+	Set(key string, responseBytes []byte)
+	Delete(key string)
+}
+
+// MemoryCache is an in-memory Cache. The zero value is not usable; use
+// NewMemoryCache.
+type MemoryCache struct {
+	mu    sync.RWMutex
+	items map[string][]byte
+}
+
+// NewMemoryCache returns an empty in-memory Cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{items: make(map[string][]byte)}
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	b, ok := c.items[key]
+	return b, ok
+}
+
+func (c *MemoryCache) Set(key string, responseBytes []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = responseBytes
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+}
+
+// DiskCache is a Cache backed by files in a directory, one per cache key.
+type DiskCache struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewDiskCache returns a Cache that stores entries as files under dir,
+// creating dir if it does not already exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+func (c *DiskCache) Set(key string, responseBytes []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ioutil.WriteFile(c.path(key), responseBytes, 0644)
+}
+
+func (c *DiskCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	os.Remove(c.path(key))
+}
+
+func (c *DiskCache) path(key string) string {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return c.dir + "/" + strconv.FormatUint(h.Sum64(), 16)
+}
+
+// Transport wraps an underlying http.RoundTripper, serving conditional
+// requests out of Cache and blocking on Limiter before every request it
+// sends, conditional requests included.
+type Transport struct {
+	Transport http.RoundTripper
+	Cache     Cache
+	Limiter   *rate.Limiter
+}
+
+// Option configures a Transport returned by New.
+type Option func(*Transport)
+
+// WithTransport sets the underlying RoundTripper used for requests that miss
+// the cache. Defaults to http.DefaultTransport.
+func WithTransport(t http.RoundTripper) Option {
+	return func(tr *Transport) { tr.Transport = t }
+}
+
+// WithCache sets the Cache implementation. Defaults to an empty MemoryCache.
+func WithCache(c Cache) Option {
+	return func(tr *Transport) { tr.Cache = c }
+}
+
+// WithLimiter sets the rate limiter applied before each request sent,
+// conditional requests included. If unset, requests are not rate limited.
+func WithLimiter(l *rate.Limiter) Option {
+	return func(tr *Transport) { tr.Limiter = l }
+}
+
+// New returns a Transport configured with opts, defaulting to
+// http.DefaultTransport and an empty in-memory cache.
+func New(opts ...Option) *Transport {
+	t := &Transport{
+		Transport: http.DefaultTransport,
+		Cache:     NewMemoryCache(),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// RoundTrip implements http.RoundTripper. It attaches conditional request
+// headers from any cached response, adjusts Limiter from every response's
+// X-RateLimit-Remaining/X-RateLimit-Reset headers, replays the cache on a
+// 304, and otherwise stores a fresh 200 response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := cacheKey(req)
+
+	var cached *http.Response
+	if b, ok := t.Cache.Get(key); ok {
+		cached, _ = http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), req)
+		if cached != nil {
+			// http.RoundTripper must not mutate the request it's given, so
+			// set conditional headers on a clone rather than req itself.
+			req = cloneRequest(req)
+			if etag := cached.Header.Get("ETag"); etag != "" {
+				req.Header.Set("If-None-Match", etag)
+			}
+			if lastMod := cached.Header.Get("Last-Modified"); lastMod != "" {
+				req.Header.Set("If-Modified-Since", lastMod)
+			}
+		}
+	}
+
+	if t.Limiter != nil {
+		if err := t.Limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.adjustLimiter(resp)
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		cached.Header.Set(FromCacheHeader, "1")
+		return cached, nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		if dump, err := httputil.DumpResponse(resp, true); err == nil {
+			t.Cache.Set(key, dump)
+			resp.Body = ioutil.NopCloser(bytes.NewReader(extractBody(dump)))
+		}
+	}
+
+	return resp, nil
+}
+
+// adjustLimiter retunes the limiter from the remaining quota and reset time
+// GitHub reports on every response, so the limiter reflects reality
+// even if it was constructed with a stale or default value.
+func (t *Transport) adjustLimiter(resp *http.Response) {
+	if t.Limiter == nil {
+		return
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil || remaining <= 0 {
+		return
+	}
+
+	resetUnix, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	untilReset := time.Until(time.Unix(resetUnix, 0))
+	if untilReset <= 0 {
+		return
+	}
+
+	t.Limiter.SetLimit(rate.Limit(float64(remaining) / untilReset.Seconds()))
+}
+
+// cloneRequest returns a shallow copy of req with its own Header map, so
+// callers can set per-attempt headers without mutating the request the
+// caller passed in.
+func cloneRequest(req *http.Request) *http.Request {
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		clone.Header[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String()
+}
+
+func extractBody(dump []byte) []byte {
+	idx := bytes.Index(dump, []byte("\r\n\r\n"))
+	if idx == -1 {
+		return nil
+	}
+	return dump[idx+4:]
+}
@@ -0,0 +1,168 @@
+// Package webhook receives GitHub webhook deliveries and applies them to a
+// githubservice.GithubService's corpus, so dashboards stay fresh without
+// repeatedly re-paginating the API.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/valentim/marvin/githubservice"
+	"golang.org/x/net/context"
+)
+
+// Handler is an http.Handler that verifies GitHub's X-Hub-Signature-256 HMAC
+// and dispatches issues, issue_comment, pull_request, push and label events
+// into Service via ApplyEvent.
+type Handler struct {
+	Secret  []byte
+	Service *githubservice.GithubService
+}
+
+// New returns a Handler that verifies deliveries with secret and applies
+// them to service.
+func New(secret []byte, service *githubservice.GithubService) *Handler {
+	return &Handler{Secret: secret, Service: service}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "error reading request body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.validSignature(r.Header.Get("X-Hub-Signature-256"), body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	eventType := r.Header.Get("X-GitHub-Event")
+	if err := h.Service.ApplyEvent(r.Context(), eventType, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) validSignature(header string, body []byte) bool {
+	const prefix = "sha256="
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(header, prefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, h.Secret)
+	mac.Write(body)
+
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Replay ingests owner/repo's /repos/{owner}/{repo}/events feed and applies
+// every event through Service.ApplyEvent, closing the gap between the last
+// webhook delivery received and a process restart.
+func (h *Handler) Replay(ctx context.Context, client *github.Client, owner, repo string) error {
+	opt := &github.ListOptions{PerPage: 100}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		events, resp, err := client.Activity.ListRepositoryEvents(ctx, owner, repo, opt)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			eventType := webhookEventType(event.GetType())
+			if eventType == "" {
+				continue
+			}
+
+			payload, err := event.ParsePayload()
+			if err != nil {
+				continue
+			}
+
+			raw, err := json.Marshal(payload)
+			if err != nil {
+				continue
+			}
+
+			raw, err = withRepository(raw, event.GetRepo())
+			if err != nil {
+				continue
+			}
+
+			if err := h.Service.ApplyEvent(ctx, eventType, raw); err != nil {
+				return fmt.Errorf("webhook: replaying %s event: %v", eventType, err)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return nil
+}
+
+// withRepository ensures raw carries a top-level "repository" field sourced
+// from the events-feed Event envelope. Unlike a real webhook delivery, the
+// typed payload returned by Event.ParsePayload() doesn't embed Repo, so
+// ApplyEvent's decoders would otherwise see it as missing and reject every
+// replayed event. Merging it in generically (rather than type-switching on
+// the payload) avoids depending on each event type's Repo field having
+// identical shape.
+func withRepository(raw []byte, repo *github.Repository) ([]byte, error) {
+	if repo == nil {
+		return raw, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	repoJSON, err := json.Marshal(repo)
+	if err != nil {
+		return nil, err
+	}
+	fields["repository"] = repoJSON
+
+	return json.Marshal(fields)
+}
+
+// webhookEventType maps a go-github events-feed Event.Type (e.g.
+// "IssuesEvent") to the X-GitHub-Event header name ApplyEvent expects (e.g.
+// "issues"). Event types with no webhook equivalent return "".
+func webhookEventType(apiEventType string) string {
+	switch apiEventType {
+	case "IssuesEvent":
+		return "issues"
+	case "IssueCommentEvent":
+		return "issue_comment"
+	case "PullRequestEvent":
+		return "pull_request"
+	case "PushEvent":
+		return "push"
+	case "LabelEvent":
+		return "label"
+	default:
+		return ""
+	}
+}